@@ -0,0 +1,23 @@
+package common
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// PromptApprove prints message and asks the operator to confirm before applying the pending
+// change sets; it does not print the change sets itself, their summary is assumed to already
+// be rendered by the caller (via RenderChangeSet).  It is the prompt for an `--approve` flag
+// on `up` (see EnablePlanMode), which is not part of this tree; nothing here calls it.
+func PromptApprove(message string) (bool, error) {
+	fmt.Printf("%s [y/N]: ", message)
+	reader := bufio.NewReader(os.Stdin)
+	response, err := reader.ReadString('\n')
+	if err != nil {
+		return false, err
+	}
+	response = strings.ToLower(strings.TrimSpace(response))
+	return response == "y" || response == "yes", nil
+}
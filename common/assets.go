@@ -0,0 +1,21 @@
+package common
+
+// AssetManager renders and persists the CloudFormation bundle for a set of stacks to disk
+// instead of applying them, so the exact templates and parameter values that would be
+// applied can be reviewed (`--assets-out`) and later fed straight to CloudFormation without
+// re-running the workflow (`--assets-in`)
+type AssetManager interface {
+	StackUpserter
+	StackWaiter
+
+	// WriteManifest finalizes the bundle by writing the top-level ordered apply plan
+	// covering every stack written to the bundle so far
+	WriteManifest() error
+}
+
+// BundleApplier creates/updates a stack directly from an already-rendered CloudFormation
+// template body read back from an asset bundle (see AssetManager), rather than resolving
+// templateName as a template asset to render — which is what StackUpserter.UpsertStack does
+type BundleApplier interface {
+	ApplyRenderedStack(stackName string, templateBody string, stackParameters map[string]string, tags map[string]string, roleArn string) error
+}
@@ -0,0 +1,18 @@
+package common
+
+// EnvProviderEks upserts an EKS control plane and managed node group, and drives
+// service deployments through the Kubernetes API rather than ECS
+const EnvProviderEks EnvProvider = "eks"
+
+// KubeconfigOpts controls how a kubeconfig is generated for an EKS environment
+type KubeconfigOpts struct {
+	// Path is the kubeconfig file to write/merge into.  Defaults to ~/.kube/config
+	Path string
+
+	// RoleArn, when set, is assumed by the `aws eks get-token` exec plugin via `--role-arn`
+	RoleArn string
+
+	// SessionName, when set, is passed to the exec plugin as the AWS_ROLE_SESSION_NAME
+	// environment variable, whether or not RoleArn is set
+	SessionName string
+}
@@ -1,74 +1,244 @@
 package common
 
 import (
-	"errors"
 	"fmt"
-	"github.com/speedata/gogit"
-	"github.com/tcnksm/go-gitconfig"
-	"os"
-	"path"
+	"net/url"
 	"regexp"
+	"strings"
+	"time"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/tcnksm/go-gitconfig"
 )
 
-func findGitRevision(file string) (string, error) {
-	gitDir, err := findGitDirectory(file)
+// GitProvider identifies the hosting service behind a repository's origin URL
+type GitProvider string
+
+// GitProvider values recognized when parsing a repository's origin URL
+const (
+	GitProviderGitHub           GitProvider = "github"
+	GitProviderGitHubEnterprise GitProvider = "github-enterprise"
+	GitProviderGitLab           GitProvider = "gitlab"
+	GitProviderBitbucket        GitProvider = "bitbucket"
+	GitProviderBitbucketServer  GitProvider = "bitbucket-server"
+	GitProviderCodeCommit       GitProvider = "codecommit"
+	GitProviderUnknown          GitProvider = "unknown"
+)
+
+// GitHubEnterpriseHosts lists additional hosts, beyond github.com, that should be treated as
+// GitHub Enterprise rather than falling through to the generic GitLab/Bitbucket Server guesses.
+// Intended to be set once, from `repo.gitHubEnterpriseHosts` in mu.yml, by the config loader
+// (common/config.go) before FindGitSource runs -- that file isn't part of this tree, so nothing
+// currently assigns to this var and it stays nil.
+var GitHubEnterpriseHosts []string
+
+// GitSource describes the repository backing `mu.yml`'s `repo` config, parsed from the
+// `origin` remote so pipeline stacks can pick the matching CodePipeline source action
+type GitSource struct {
+	Provider GitProvider
+	Host     string
+	Owner    string
+	Repo     string
+}
+
+// Slug returns the `owner/repo` identifier used in stack names and tags.  CodeCommit
+// repositories have no owner, so it degrades to just the repo name.
+func (s GitSource) Slug() string {
+	if s.Owner == "" {
+		return s.Repo
+	}
+	return fmt.Sprintf("%s/%s", s.Owner, s.Repo)
+}
+
+var scpLikeURLRegex = regexp.MustCompile(`^(?:(?P<user>[^@]+)@)?(?P<host>[^:/]+):(?P<path>.+)$`)
+
+// GitRevision describes the repository's current commit, enriched with what a CI
+// environment needs beyond the bare SHA: the tag pointing at the commit (if any), whether the
+// worktree is dirty, and whether the commit carries a GPG signature.  String returns ShortSHA,
+// the legacy short-SHA form, for consumers that still want ctx.Config.Repo.Revision as a string.
+type GitRevision struct {
+	SHA           string
+	ShortSHA      string
+	Branch        string
+	Tag           string
+	Dirty         bool
+	CommitterDate time.Time
+	Signed        bool
+}
+
+func (r GitRevision) String() string {
+	return r.ShortSHA
+}
+
+// FindGitRevision resolves the current commit of the repository containing file, correctly
+// handling detached HEAD, git worktrees (where .git is a file pointing to `gitdir:`),
+// submodules, and packed refs via go-git's own repository discovery.  Exported so the config
+// loader can call it to populate `ctx.Config.Repo.Revision`: that field must be typed
+// `*GitRevision` (not the bare string it used to be), since callers like
+// workflows/environment_upsert.go now read `.Dirty` off it directly and only fall back to the
+// `.String()` method where the legacy short-SHA string is still wanted.
+func FindGitRevision(file string) (*GitRevision, error) {
+	repo, err := git.PlainOpenWithOptions(file, &git.PlainOpenOptions{DetectDotGit: true})
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	log.Debugf("Loading revision from git directory '%s'", gitDir)
 
-	repository, err := gogit.OpenRepository(gitDir)
+	head, err := repo.Head()
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	log.Debugf("Loading revision from git HEAD '%s'", head.Hash())
+
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+
+	revision := &GitRevision{
+		SHA:           commit.Hash.String(),
+		ShortSHA:      commit.Hash.String()[:7],
+		CommitterDate: commit.Committer.When,
+		Signed:        commit.PGPSignature != "",
+	}
+
+	if head.Name().IsBranch() {
+		revision.Branch = head.Name().Short()
 	}
-	ref, err := repository.LookupReference("HEAD")
+
+	if tag, err := nearestTag(repo, head.Hash()); err == nil {
+		revision.Tag = tag
+	}
+
+	if worktree, err := repo.Worktree(); err == nil {
+		if status, err := worktree.Status(); err == nil {
+			revision.Dirty = !status.IsClean()
+		}
+	}
+
+	return revision, nil
+}
+
+// nearestTag walks the repository's tag refs for one pointing at (or, for annotated tags,
+// targeting) the given commit hash.  Tags on ancestor commits are not considered, so a commit
+// with no tag of its own yields an error.
+func nearestTag(repo *git.Repository, hash plumbing.Hash) (string, error) {
+	tagRefs, err := repo.Tags()
 	if err != nil {
 		return "", err
 	}
-	ci, err := repository.LookupCommit(ref.Oid)
+
+	var found string
+	err = tagRefs.ForEach(func(ref *plumbing.Reference) error {
+		commitHash := ref.Hash()
+		if tagObj, err := repo.TagObject(ref.Hash()); err == nil {
+			commitHash = tagObj.Target
+		}
+		if commitHash == hash {
+			found = ref.Name().Short()
+		}
+		return nil
+	})
 	if err != nil {
 		return "", err
 	}
-	return string(ci.Id().String()[:7]), nil
+	if found == "" {
+		return "", fmt.Errorf("no tag found for %s", hash)
+	}
+	return found, nil
 }
-func findGitSlug() (string, error) {
-	url, err := gitconfig.OriginURL()
+
+// FindGitSlug returns the `owner/repo` slug for the origin remote.  Kept for callers that
+// only need the legacy string form; prefer FindGitSource for anything provider-aware.
+func FindGitSlug() (string, error) {
+	source, err := FindGitSource()
 	if err != nil {
 		return "", err
 	}
+	return source.Slug(), nil
+}
 
-	httpRegex := regexp.MustCompile("^http(s?)://.*github.com.*/(.+)/(.+).git$")
-	sshRegex := regexp.MustCompile("github.com:(.+)/(.+).git$")
-	if matches := httpRegex.FindStringSubmatch(url); matches != nil {
-		return fmt.Sprintf("%s/%s", matches[2], matches[3]), nil
-	} else if matches := sshRegex.FindStringSubmatch(url); matches != nil {
-		return fmt.Sprintf("%s/%s", matches[1], matches[2]), nil
+// FindGitSource parses the origin remote URL into a structured GitSource, recognizing
+// GitHub, GitHub Enterprise, GitLab (.com and self-hosted), Bitbucket Cloud, Bitbucket
+// Server, and AWS CodeCommit.  Exported so the config loader can populate
+// ctx.Config.Repo.Source, and the pipeline workflow can read it to pick a CodePipeline
+// source action (see PipelineSourceStackParams).
+func FindGitSource() (GitSource, error) {
+	rawURL, err := gitconfig.OriginURL()
+	if err != nil {
+		return GitSource{}, err
 	}
-	return url, nil
+	return parseGitSource(rawURL, GitHubEnterpriseHosts)
 }
 
-func findGitDirectory(fromFile string) (string, error) {
-	log.Debugf("Searching for git directory in %s", fromFile)
-	fi, err := os.Stat(fromFile)
+func parseGitSource(rawURL string, enterpriseHosts []string) (GitSource, error) {
+	host, owner, repo, err := splitGitURL(rawURL)
 	if err != nil {
-		return "", err
+		return GitSource{}, err
 	}
 
-	var dir string
-	if fi.Mode().IsDir() {
-		dir = fromFile
-	} else {
-		dir = path.Dir(fromFile)
+	var provider GitProvider
+	switch {
+	case host == "github.com":
+		provider = GitProviderGitHub
+	case host == "gitlab.com":
+		provider = GitProviderGitLab
+	case host == "bitbucket.org":
+		provider = GitProviderBitbucket
+	case strings.Contains(host, "codecommit") && strings.HasSuffix(host, "amazonaws.com"):
+		provider = GitProviderCodeCommit
+	case hostInList(enterpriseHosts, host):
+		provider = GitProviderGitHubEnterprise
+	case strings.Contains(host, "gitlab"):
+		provider = GitProviderGitLab
+	case strings.Contains(host, "bitbucket"):
+		provider = GitProviderBitbucketServer
+	default:
+		provider = GitProviderUnknown
 	}
 
-	gitPath := path.Join(dir, ".git")
-	fi, err = os.Stat(gitPath)
-	if err == nil && fi.Mode().IsDir() {
-		return gitPath, nil
-	} else if dir == "/" {
-		return "", errors.New("Unable to find git repo")
-	} else {
-		return findGitDirectory(path.Dir(dir))
+	return GitSource{Provider: provider, Host: host, Owner: owner, Repo: repo}, nil
+}
+
+func hostInList(hosts []string, host string) bool {
+	for _, h := range hosts {
+		if strings.EqualFold(h, host) {
+			return true
+		}
 	}
+	return false
+}
 
+// splitGitURL normalizes both scp-like (`git@host:owner/repo.git`) and URL-style
+// (`https://host/owner/repo.git`, `ssh://git@host/owner/repo.git`) origin remotes and
+// extracts the host plus the owner/repo path segments.
+func splitGitURL(rawURL string) (host string, owner string, repo string, err error) {
+	normalized := rawURL
+	if !strings.Contains(rawURL, "://") {
+		if matches := scpLikeURLRegex.FindStringSubmatch(rawURL); matches != nil {
+			normalized = fmt.Sprintf("ssh://%s@%s/%s", matches[1], matches[2], matches[3])
+		}
+	}
+
+	u, err := url.Parse(normalized)
+	if err != nil {
+		return "", "", "", err
+	}
+	if u.Host == "" {
+		return "", "", "", fmt.Errorf("unable to determine host from git url '%s'", rawURL)
+	}
+
+	trimmedPath := strings.TrimSuffix(strings.TrimPrefix(u.Path, "/"), ".git")
+
+	// CodeCommit repo URLs encode the repo name as the last segment, e.g. /v1/repos/<repo>
+	if strings.Contains(u.Host, "codecommit") {
+		segments := strings.Split(trimmedPath, "/")
+		return u.Host, "", segments[len(segments)-1], nil
+	}
+
+	segments := strings.SplitN(trimmedPath, "/", 2)
+	if len(segments) != 2 || segments[0] == "" || segments[1] == "" {
+		return "", "", "", fmt.Errorf("unable to parse owner/repo from git url '%s'", rawURL)
+	}
+	return u.Host, segments[0], segments[1], nil
 }
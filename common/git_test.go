@@ -0,0 +1,97 @@
+package common
+
+import "testing"
+
+func TestSplitGitURLScpLike(t *testing.T) {
+	host, owner, repo, err := splitGitURL("git@github.com:stelligent/mu.git")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if host != "github.com" || owner != "stelligent" || repo != "mu" {
+		t.Errorf("got (%q, %q, %q), want (github.com, stelligent, mu)", host, owner, repo)
+	}
+}
+
+func TestSplitGitURLHTTPSWithPort(t *testing.T) {
+	host, owner, repo, err := splitGitURL("https://bitbucket.example.com:7999/scm/owner/repo.git")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if host != "bitbucket.example.com:7999" || owner != "scm" || repo != "owner/repo" {
+		t.Errorf("got (%q, %q, %q)", host, owner, repo)
+	}
+}
+
+func TestSplitGitURLSSHStyle(t *testing.T) {
+	host, owner, repo, err := splitGitURL("ssh://git@gitlab.example.com/group/project.git")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if host != "gitlab.example.com" || owner != "group" || repo != "project" {
+		t.Errorf("got (%q, %q, %q)", host, owner, repo)
+	}
+}
+
+func TestSplitGitURLCodeCommit(t *testing.T) {
+	host, owner, repo, err := splitGitURL("https://git-codecommit.us-east-1.amazonaws.com/v1/repos/my-repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if host != "git-codecommit.us-east-1.amazonaws.com" || owner != "" || repo != "my-repo" {
+		t.Errorf("got (%q, %q, %q), want (git-codecommit.us-east-1.amazonaws.com, \"\", my-repo)", host, owner, repo)
+	}
+}
+
+func TestSplitGitURLMissingOwner(t *testing.T) {
+	if _, _, _, err := splitGitURL("https://github.com/mu.git"); err == nil {
+		t.Error("expected error for url missing an owner segment, got nil")
+	}
+}
+
+func TestSplitGitURLMalformed(t *testing.T) {
+	if _, _, _, err := splitGitURL("not a url"); err == nil {
+		t.Error("expected error for malformed url, got nil")
+	}
+}
+
+func TestParseGitSourceProviders(t *testing.T) {
+	cases := []struct {
+		name     string
+		rawURL   string
+		hosts    []string
+		provider GitProvider
+	}{
+		{"github", "git@github.com:stelligent/mu.git", nil, GitProviderGitHub},
+		{"github enterprise", "https://git.internal.example.com/stelligent/mu.git", []string{"git.internal.example.com"}, GitProviderGitHubEnterprise},
+		{"gitlab.com", "git@gitlab.com:group/project.git", nil, GitProviderGitLab},
+		{"self-hosted gitlab", "https://gitlab.example.com/group/project.git", nil, GitProviderGitLab},
+		{"bitbucket cloud", "git@bitbucket.org:owner/repo.git", nil, GitProviderBitbucket},
+		{"bitbucket server", "https://bitbucket.example.com/scm/owner/repo.git", nil, GitProviderBitbucketServer},
+		{"codecommit", "https://git-codecommit.us-east-1.amazonaws.com/v1/repos/my-repo", nil, GitProviderCodeCommit},
+		{"unrecognized host", "https://example.com/owner/repo.git", nil, GitProviderUnknown},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			source, err := parseGitSource(c.rawURL, c.hosts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if source.Provider != c.provider {
+				t.Errorf("parseGitSource(%q) provider = %q, want %q", c.rawURL, source.Provider, c.provider)
+			}
+		})
+	}
+}
+
+func TestGitSourceSlug(t *testing.T) {
+	withOwner := GitSource{Owner: "stelligent", Repo: "mu"}
+	if withOwner.Slug() != "stelligent/mu" {
+		t.Errorf("Slug() = %q, want stelligent/mu", withOwner.Slug())
+	}
+
+	codeCommit := GitSource{Repo: "my-repo"}
+	if codeCommit.Slug() != "my-repo" {
+		t.Errorf("Slug() = %q, want my-repo", codeCommit.Slug())
+	}
+}
@@ -0,0 +1,73 @@
+package common
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/fatih/color"
+)
+
+// RenderChangeSet prints a colorized, grouped diff for a single stack's pending change set:
+// resource adds/modifies/removes (flagging replacements) followed by the parameter and tag
+// deltas.  A change set with no resource, parameter, or tag changes prints nothing beyond
+// the "no changes" notice.
+func RenderChangeSet(changeSet *StackChangeSet) {
+	fmt.Printf("\n%s %s (%s)\n", color.New(color.Bold).Sprint("Stack:"), changeSet.StackName, changeSet.ChangeSetType)
+
+	if len(changeSet.ResourceChanges) == 0 && len(changeSet.ParameterChanges) == 0 && len(changeSet.TagChanges) == 0 {
+		fmt.Println("  (no changes)")
+		return
+	}
+
+	for _, rc := range changeSet.ResourceChanges {
+		line := fmt.Sprintf("  %s  %-10s %-30s %s", actionGlyph(rc.Action), rc.ResourceType, rc.LogicalResourceID, rc.Action)
+		if rc.Replacement == "True" {
+			line += color.New(color.FgRed).Sprint(" (replacement)")
+		} else if rc.Replacement == "Conditional" {
+			line += color.New(color.FgYellow).Sprint(" (conditional replacement)")
+		}
+		fmt.Println(actionColor(rc.Action).Sprint(line))
+	}
+
+	for _, name := range sortedValueChangeKeys(changeSet.ParameterChanges) {
+		change := changeSet.ParameterChanges[name]
+		fmt.Printf("  %s parameter %s: %q -> %q\n", color.New(color.FgCyan).Sprint("~"), name, change.Before, change.After)
+	}
+	for _, name := range sortedValueChangeKeys(changeSet.TagChanges) {
+		change := changeSet.TagChanges[name]
+		fmt.Printf("  %s tag %s: %q -> %q\n", color.New(color.FgCyan).Sprint("~"), name, change.Before, change.After)
+	}
+}
+
+// sortedValueChangeKeys returns changes' keys in lexical order so RenderChangeSet's output is
+// deterministic across runs instead of following Go's randomized map iteration order
+func sortedValueChangeKeys(changes map[string]ValueChange) []string {
+	keys := make([]string, 0, len(changes))
+	for name := range changes {
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func actionGlyph(action string) string {
+	switch action {
+	case "Add":
+		return "+"
+	case "Remove":
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+func actionColor(action string) *color.Color {
+	switch action {
+	case "Add":
+		return color.New(color.FgGreen)
+	case "Remove":
+		return color.New(color.FgRed)
+	default:
+		return color.New(color.FgYellow)
+	}
+}
@@ -0,0 +1,32 @@
+package common
+
+// ResourceChange describes a single resource-level change computed from a CloudFormation
+// change set
+type ResourceChange struct {
+	Action            string // Add, Modify, Remove
+	LogicalResourceID string
+	ResourceType      string
+	Replacement       string // True, False, Conditional
+	Scope             []string
+}
+
+// ValueChange captures a parameter or tag's value before and after a pending change
+type ValueChange struct {
+	Before string
+	After  string
+}
+
+// StackChangeSet is the full pending diff for one stack: the change set's resource-level
+// changes plus the parameter and tag deltas between the current stack and the pending change
+type StackChangeSet struct {
+	StackName        string
+	ChangeSetType    string // CREATE or UPDATE
+	ResourceChanges  []ResourceChange
+	ParameterChanges map[string]ValueChange
+	TagChanges       map[string]ValueChange
+}
+
+// StackPlanner computes the pending change set for a stack without applying it
+type StackPlanner interface {
+	PlanStack(stackName string, templateName string, templateData interface{}, stackParameters map[string]string, tags map[string]string, roleArn string) (*StackChangeSet, error)
+}
@@ -0,0 +1,127 @@
+package aws
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"text/template"
+
+	"github.com/stelligent/mu/common"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// assetManager implements common.AssetManager by rendering each stack's CloudFormation
+// template and parameters to '<outputDir>/<stackName>/' instead of calling CloudFormation
+type assetManager struct {
+	common.StackManager // delegate ImageFinder/AZCounter/stack lookups to the real manager
+	outputDir           string
+	plan                []assetPlanEntry
+}
+
+type assetPlanEntry struct {
+	StackName string   `yaml:"stackName"`
+	Template  string   `yaml:"template"`
+	DependsOn []string `yaml:"dependsOn,omitempty"`
+}
+
+// stackDependencies captures the upsert ordering between the stack types mu manages; a
+// stack depends on every stack type listed here that shares its namespace/environment
+var stackDependencies = map[common.StackType][]common.StackType{
+	common.StackTypeVpc:          {},
+	common.StackTypeTarget:       {},
+	common.StackTypeLoadBalancer: {common.StackTypeVpc, common.StackTypeTarget},
+	common.StackTypeEnv:          {common.StackTypeVpc, common.StackTypeTarget, common.StackTypeLoadBalancer},
+	common.StackTypeService:      {common.StackTypeEnv},
+	common.StackTypeDatabase:     {common.StackTypeEnv},
+	common.StackTypePipeline:     {},
+}
+
+func newAssetManager(realStackManager common.StackManager, outputDir string) (*assetManager, error) {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, err
+	}
+	return &assetManager{StackManager: realStackManager, outputDir: outputDir}, nil
+}
+
+// UpsertStack renders the stack's template, parameters, tags and dependency metadata to disk
+// instead of creating/updating the CloudFormation stack
+func (mgr *assetManager) UpsertStack(stackName string, templateName string, templateData interface{}, stackParameters map[string]string, tags map[string]string, roleArn string) error {
+	stackDir := path.Join(mgr.outputDir, stackName)
+	if err := os.MkdirAll(stackDir, 0755); err != nil {
+		return err
+	}
+
+	templateBody, err := renderTemplateAsset(templateName, templateData)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path.Join(stackDir, filepath.Base(templateName)), []byte(templateBody), 0644); err != nil {
+		return err
+	}
+	if err := writeAssetJSON(path.Join(stackDir, "parameters.json"), stackParameters); err != nil {
+		return err
+	}
+	if err := writeAssetJSON(path.Join(stackDir, "tags.json"), tags); err != nil {
+		return err
+	}
+
+	stackType := common.StackType(tags["type"])
+	dependsOn := make([]string, 0)
+	for _, depType := range stackDependencies[stackType] {
+		dependsOn = append(dependsOn, string(depType))
+	}
+	if err := writeAssetJSON(path.Join(stackDir, "dependencies.json"), dependsOn); err != nil {
+		return err
+	}
+
+	mgr.plan = append(mgr.plan, assetPlanEntry{StackName: stackName, Template: templateName, DependsOn: dependsOn})
+
+	log.Noticef("Wrote asset bundle for stack '%s' to '%s'", stackName, stackDir)
+	return nil
+}
+
+// AwaitFinalStatus is a no-op for asset generation; there is no stack to wait on
+func (mgr *assetManager) AwaitFinalStatus(stackName string) *common.Stack {
+	return &common.Stack{Name: stackName, Status: "CREATE_COMPLETE"}
+}
+
+// WriteManifest writes the top-level ordered apply plan for every stack in the bundle
+func (mgr *assetManager) WriteManifest() error {
+	manifestBytes, err := yaml.Marshal(mgr.plan)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path.Join(mgr.outputDir, "manifest.yaml"), manifestBytes, 0644)
+}
+
+// renderTemplateAsset loads the named CloudFormation template asset and executes it against
+// templateData, the same rendering stackManager applies before an UpsertStack call
+func renderTemplateAsset(templateName string, templateData interface{}) (string, error) {
+	assetBody, err := Asset(templateName)
+	if err != nil {
+		return "", fmt.Errorf("unable to load template asset '%s': %v", templateName, err)
+	}
+
+	tmpl, err := template.New(templateName).Parse(string(assetBody))
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, templateData); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func writeAssetJSON(filePath string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filePath, data, 0644)
+}
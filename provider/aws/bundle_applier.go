@@ -0,0 +1,77 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/cloudformation/cloudformationiface"
+	"github.com/stelligent/mu/common"
+)
+
+// bundleApplier implements common.BundleApplier by creating/updating a stack from a literal
+// template body, instead of resolving a template asset name the way stackManager/stackPlanner
+// do — the asset bundle's template is already fully rendered, so there is nothing to look up
+type bundleApplier struct {
+	cfnAPI cloudformationiface.CloudFormationAPI
+}
+
+// NewBundleApplier creates a common.BundleApplier bound to the given session, for
+// `--assets-in apply` to feed a previously generated bundle straight to CloudFormation
+func NewBundleApplier(sess *session.Session) common.BundleApplier {
+	return &bundleApplier{cfnAPI: cloudformation.New(sess)}
+}
+
+// ApplyRenderedStack creates stackName if it doesn't exist, or updates it if it does, using
+// templateBody verbatim
+func (applier *bundleApplier) ApplyRenderedStack(stackName string, templateBody string, stackParameters map[string]string, tags map[string]string, roleArn string) error {
+	cfnParams := make([]*cloudformation.Parameter, 0, len(stackParameters))
+	for k, v := range stackParameters {
+		cfnParams = append(cfnParams, &cloudformation.Parameter{ParameterKey: aws.String(k), ParameterValue: aws.String(v)})
+	}
+	cfnTags := make([]*cloudformation.Tag, 0, len(tags))
+	for k, v := range tags {
+		cfnTags = append(cfnTags, &cloudformation.Tag{Key: aws.String(k), Value: aws.String(v)})
+	}
+	capabilities := aws.StringSlice([]string{cloudformation.CapabilityCapabilityNamedIam})
+	// an empty RoleARN fails CloudFormation's request validation, so omit it when unset
+	var roleARN *string
+	if roleArn != "" {
+		roleARN = aws.String(roleArn)
+	}
+
+	_, err := applier.cfnAPI.DescribeStacks(&cloudformation.DescribeStacksInput{StackName: aws.String(stackName)})
+	if err != nil {
+		log.Debugf("Stack '%s' does not exist yet, creating from bundle", stackName)
+		if _, err := applier.cfnAPI.CreateStack(&cloudformation.CreateStackInput{
+			StackName:    aws.String(stackName),
+			TemplateBody: aws.String(templateBody),
+			Parameters:   cfnParams,
+			Tags:         cfnTags,
+			Capabilities: capabilities,
+			RoleARN:      roleARN,
+		}); err != nil {
+			return fmt.Errorf("unable to create stack '%s' from bundle: %v", stackName, err)
+		}
+		return applier.cfnAPI.WaitUntilStackCreateComplete(&cloudformation.DescribeStacksInput{StackName: aws.String(stackName)})
+	}
+
+	log.Debugf("Stack '%s' exists, updating from bundle", stackName)
+	_, err = applier.cfnAPI.UpdateStack(&cloudformation.UpdateStackInput{
+		StackName:    aws.String(stackName),
+		TemplateBody: aws.String(templateBody),
+		Parameters:   cfnParams,
+		Tags:         cfnTags,
+		Capabilities: capabilities,
+		RoleARN:      roleARN,
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "No updates are to be performed") {
+			return nil
+		}
+		return fmt.Errorf("unable to update stack '%s' from bundle: %v", stackName, err)
+	}
+	return applier.cfnAPI.WaitUntilStackUpdateComplete(&cloudformation.DescribeStacksInput{StackName: aws.String(stackName)})
+}
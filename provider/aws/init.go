@@ -35,14 +35,24 @@ func setupSessOptions(region string,
 	return sessOptions
 }
 
-func initializeManagers(sess *session.Session, ctx *common.Context, dryrunPath string, skipVersionCheck bool) error {
+func initializeManagers(sess *session.Session, ctx *common.Context, assetsOutPath string, skipVersionCheck bool) error {
 	var err error
 	// initialize StackManager
-	ctx.StackManager, err = newStackManager(sess, ctx.ExtensionsManager, dryrunPath, skipVersionCheck)
+	ctx.StackManager, err = newStackManager(sess, ctx.ExtensionsManager, skipVersionCheck)
 	if err != nil {
 		return err
 	}
 
+	// when --assets-out is given, substitute an AssetManager that writes the rendered
+	// CloudFormation bundle to disk in place of the real StackManager's upsert/wait behavior
+	if assetsOutPath != common.Empty {
+		assetMgr, err := newAssetManager(ctx.StackManager, assetsOutPath)
+		if err != nil {
+			return err
+		}
+		ctx.StackManager = assetMgr
+	}
+
 	// initialize ClusterManager
 	ctx.ClusterManager, err = newClusterManager(sess)
 	if err != nil {
@@ -111,8 +121,10 @@ func initializeManagers(sess *session.Session, ctx *common.Context, dryrunPath s
 	return nil
 }
 
-// InitializeContext loads manager objects
-func InitializeContext(ctx *common.Context, profile string, assumeRole string, region string, dryrunPath string, skipVersionCheck bool, proxy string) error {
+// InitializeContext loads manager objects.  When assetsOutPath is non-empty, every workflow's
+// StackUpserter/StackWaiter is backed by an AssetManager that writes the CloudFormation bundle
+// to that directory instead of creating/updating real stacks.
+func InitializeContext(ctx *common.Context, profile string, assumeRole string, region string, assetsOutPath string, skipVersionCheck bool, proxy string) error {
 
 	sessOptions := setupSessOptions(region, proxy, profile)
 
@@ -131,7 +143,7 @@ func InitializeContext(ctx *common.Context, profile string, assumeRole string, r
 			return err
 		}
 	}
-	err = initializeManagers(sess, ctx, dryrunPath, skipVersionCheck)
+	err = initializeManagers(sess, ctx, assetsOutPath, skipVersionCheck)
 	if err != nil {
 		return err
 	}
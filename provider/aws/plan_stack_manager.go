@@ -0,0 +1,46 @@
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/stelligent/mu/common"
+)
+
+// planStackManager implements common.StackUpserter/common.StackWaiter by planning each
+// stack instead of applying it, so the exact same workflow executor chain used for `up` can
+// be reused unmodified for `plan` — only the manager backing ctx.StackManager differs.
+type planStackManager struct {
+	common.StackManager // delegate ImageFinder/AZCounter/stack lookups to the real manager
+	planner             common.StackPlanner
+	ChangeSets          []*common.StackChangeSet
+}
+
+func newPlanStackManager(sess *session.Session, realStackManager common.StackManager) *planStackManager {
+	return &planStackManager{StackManager: realStackManager, planner: newStackPlanner(sess)}
+}
+
+// UpsertStack computes the stack's pending change set, renders it, and records it; it never
+// calls CreateStack/UpdateStack
+func (mgr *planStackManager) UpsertStack(stackName string, templateName string, templateData interface{}, stackParameters map[string]string, tags map[string]string, roleArn string) error {
+	changeSet, err := mgr.planner.PlanStack(stackName, templateName, templateData, stackParameters, tags, roleArn)
+	if err != nil {
+		return err
+	}
+	mgr.ChangeSets = append(mgr.ChangeSets, changeSet)
+	common.RenderChangeSet(changeSet)
+	return nil
+}
+
+// AwaitFinalStatus is a no-op for planning; there is nothing applied to wait on
+func (mgr *planStackManager) AwaitFinalStatus(stackName string) *common.Stack {
+	return &common.Stack{Name: stackName, Status: "CREATE_COMPLETE"}
+}
+
+// EnablePlanMode substitutes a plan-mode StackManager into ctx, so that running the normal
+// `mu env up`/`mu svc up` workflow chain against ctx prints the pending diff for every stack
+// instead of applying it.  Intended to back two cmd/ entry points that don't exist in this
+// tree yet: a `mu env plan`/`mu svc plan` subcommand that calls this and returns, and an
+// `--approve` flag on `up` that calls this first, walks the returned ChangeSets through
+// common.PromptApprove, and only then re-runs the workflow against the real ctx.StackManager.
+func EnablePlanMode(sess *session.Session, ctx *common.Context) {
+	ctx.StackManager = newPlanStackManager(sess, ctx.StackManager)
+}
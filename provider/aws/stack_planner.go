@@ -0,0 +1,143 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/cloudformation/cloudformationiface"
+	"github.com/stelligent/mu/common"
+)
+
+// stackPlanner implements common.StackPlanner by creating a CloudFormation change set,
+// waiting for it to settle, translating its resource changes plus the parameter/tag deltas
+// into a common.StackChangeSet, and deleting the change set once it has been read
+type stackPlanner struct {
+	cfnAPI cloudformationiface.CloudFormationAPI
+}
+
+func newStackPlanner(sess *session.Session) *stackPlanner {
+	return &stackPlanner{cfnAPI: cloudformation.New(sess)}
+}
+
+// PlanStack creates and describes a change set for stackName, returning the computed diff
+// without ever calling ExecuteChangeSet
+func (planner *stackPlanner) PlanStack(stackName string, templateName string, templateData interface{}, stackParameters map[string]string, tags map[string]string, roleArn string) (*common.StackChangeSet, error) {
+	templateBody, err := renderTemplateAsset(templateName, templateData)
+	if err != nil {
+		return nil, err
+	}
+
+	changeSetType := cloudformation.ChangeSetTypeUpdate
+	beforeParams := map[string]string{}
+	beforeTags := map[string]string{}
+
+	describeStacksOutput, err := planner.cfnAPI.DescribeStacks(&cloudformation.DescribeStacksInput{StackName: aws.String(stackName)})
+	if err != nil || len(describeStacksOutput.Stacks) == 0 {
+		changeSetType = cloudformation.ChangeSetTypeCreate
+	} else {
+		existing := describeStacksOutput.Stacks[0]
+		for _, p := range existing.Parameters {
+			beforeParams[aws.StringValue(p.ParameterKey)] = aws.StringValue(p.ParameterValue)
+		}
+		for _, t := range existing.Tags {
+			beforeTags[aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+		}
+	}
+
+	changeSetName := fmt.Sprintf("%s-plan-%d", stackName, time.Now().Unix())
+
+	cfnParams := make([]*cloudformation.Parameter, 0, len(stackParameters))
+	for k, v := range stackParameters {
+		cfnParams = append(cfnParams, &cloudformation.Parameter{ParameterKey: aws.String(k), ParameterValue: aws.String(v)})
+	}
+	cfnTags := make([]*cloudformation.Tag, 0, len(tags))
+	for k, v := range tags {
+		cfnTags = append(cfnTags, &cloudformation.Tag{Key: aws.String(k), Value: aws.String(v)})
+	}
+
+	_, err = planner.cfnAPI.CreateChangeSet(&cloudformation.CreateChangeSetInput{
+		StackName:     aws.String(stackName),
+		ChangeSetName: aws.String(changeSetName),
+		ChangeSetType: aws.String(changeSetType),
+		TemplateBody:  aws.String(templateBody),
+		Parameters:    cfnParams,
+		Tags:          cfnTags,
+		Capabilities:  aws.StringSlice([]string{cloudformation.CapabilityCapabilityNamedIam}),
+		RoleARN:       aws.String(roleArn),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create change set for '%s': %v", stackName, err)
+	}
+	defer planner.cfnAPI.DeleteChangeSet(&cloudformation.DeleteChangeSetInput{
+		StackName:     aws.String(stackName),
+		ChangeSetName: aws.String(changeSetName),
+	})
+
+	var describeChangeSetOutput *cloudformation.DescribeChangeSetOutput
+	for {
+		describeChangeSetOutput, err = planner.cfnAPI.DescribeChangeSet(&cloudformation.DescribeChangeSetInput{
+			StackName:     aws.String(stackName),
+			ChangeSetName: aws.String(changeSetName),
+		})
+		if err != nil {
+			return nil, err
+		}
+		status := aws.StringValue(describeChangeSetOutput.Status)
+		if status == cloudformation.ChangeSetStatusCreateComplete || strings.HasSuffix(status, "FAILED") {
+			break
+		}
+		log.Debugf("Waiting for change set '%s' on stack '%s' to complete", changeSetName, stackName)
+		time.Sleep(2 * time.Second)
+	}
+
+	if aws.StringValue(describeChangeSetOutput.Status) != cloudformation.ChangeSetStatusCreateComplete {
+		reason := aws.StringValue(describeChangeSetOutput.StatusReason)
+		if strings.Contains(reason, "didn't contain changes") {
+			return &common.StackChangeSet{StackName: stackName, ChangeSetType: changeSetType}, nil
+		}
+		return nil, fmt.Errorf("change set for '%s' ended in status %s: %s", stackName, aws.StringValue(describeChangeSetOutput.Status), reason)
+	}
+
+	resourceChanges := make([]common.ResourceChange, 0, len(describeChangeSetOutput.Changes))
+	for _, change := range describeChangeSetOutput.Changes {
+		rc := change.ResourceChange
+		scope := make([]string, 0, len(rc.Scope))
+		for _, s := range rc.Scope {
+			scope = append(scope, aws.StringValue(s))
+		}
+		resourceChanges = append(resourceChanges, common.ResourceChange{
+			Action:            aws.StringValue(rc.Action),
+			LogicalResourceID: aws.StringValue(rc.LogicalResourceId),
+			ResourceType:      aws.StringValue(rc.ResourceType),
+			Replacement:       aws.StringValue(rc.Replacement),
+			Scope:             scope,
+		})
+	}
+
+	return &common.StackChangeSet{
+		StackName:        stackName,
+		ChangeSetType:    changeSetType,
+		ResourceChanges:  resourceChanges,
+		ParameterChanges: diffStringMaps(beforeParams, stackParameters),
+		TagChanges:       diffStringMaps(beforeTags, tags),
+	}, nil
+}
+
+func diffStringMaps(before map[string]string, after map[string]string) map[string]common.ValueChange {
+	changes := make(map[string]common.ValueChange)
+	for k, afterVal := range after {
+		if beforeVal, ok := before[k]; !ok || beforeVal != afterVal {
+			changes[k] = common.ValueChange{Before: before[k], After: afterVal}
+		}
+	}
+	for k, beforeVal := range before {
+		if _, ok := after[k]; !ok {
+			changes[k] = common.ValueChange{Before: beforeVal, After: ""}
+		}
+	}
+	return changes
+}
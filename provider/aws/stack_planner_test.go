@@ -0,0 +1,51 @@
+package aws
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stelligent/mu/common"
+)
+
+func TestDiffStringMapsAddedChangedRemoved(t *testing.T) {
+	before := map[string]string{
+		"Unchanged": "same",
+		"Changed":   "old",
+		"Removed":   "gone",
+	}
+	after := map[string]string{
+		"Unchanged": "same",
+		"Changed":   "new",
+		"Added":     "fresh",
+	}
+
+	expected := map[string]common.ValueChange{
+		"Changed": {Before: "old", After: "new"},
+		"Removed": {Before: "gone", After: ""},
+		"Added":   {Before: "", After: "fresh"},
+	}
+
+	actual := diffStringMaps(before, after)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("diffStringMaps(%v, %v) = %v, want %v", before, after, actual, expected)
+	}
+}
+
+func TestDiffStringMapsNoChanges(t *testing.T) {
+	same := map[string]string{"A": "1", "B": "2"}
+
+	actual := diffStringMaps(same, same)
+	if len(actual) != 0 {
+		t.Errorf("diffStringMaps with identical maps = %v, want empty", actual)
+	}
+}
+
+func TestDiffStringMapsEmptyBefore(t *testing.T) {
+	after := map[string]string{"A": "1"}
+
+	expected := map[string]common.ValueChange{"A": {Before: "", After: "1"}}
+	actual := diffStringMaps(map[string]string{}, after)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("diffStringMaps(empty, %v) = %v, want %v", after, actual, expected)
+	}
+}
@@ -0,0 +1,76 @@
+package workflows
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+
+	"github.com/stelligent/mu/common"
+	yaml "gopkg.in/yaml.v2"
+)
+
+type assetPlanEntry struct {
+	StackName string   `yaml:"stackName"`
+	Template  string   `yaml:"template"`
+	DependsOn []string `yaml:"dependsOn,omitempty"`
+}
+
+// NewAssetBundleApplier creates a workflow that feeds a previously generated asset bundle
+// (see `--assets-out`) straight to CloudFormation, in the order recorded in its manifest,
+// without re-running template rendering or parameter resolution.  applier applies each
+// stack's already-rendered template body directly, rather than through StackUpserter.UpsertStack,
+// which would try (and fail) to resolve templateBody as a template asset name.
+//
+// Intended to back a `--assets-in <dir>` flag on `mu env apply`/`mu svc apply` that isn't part
+// of this tree yet: a cmd/ file would construct this with provider/aws.NewBundleApplier(sess)
+// and the directory the flag points at, then run the returned Executor directly (bypassing
+// NewEnvironmentUpserter/NewServiceUpserter entirely, since there is no template rendering
+// left to do).
+func NewAssetBundleApplier(applier common.BundleApplier, assetsInPath string) Executor {
+	return func() error {
+		manifestBytes, err := ioutil.ReadFile(path.Join(assetsInPath, "manifest.yaml"))
+		if err != nil {
+			return fmt.Errorf("unable to read asset manifest from '%s': %v", assetsInPath, err)
+		}
+
+		var plan []assetPlanEntry
+		if err := yaml.Unmarshal(manifestBytes, &plan); err != nil {
+			return err
+		}
+
+		for _, entry := range plan {
+			stackDir := path.Join(assetsInPath, entry.StackName)
+
+			templateBody, err := ioutil.ReadFile(path.Join(stackDir, entry.Template))
+			if err != nil {
+				return err
+			}
+
+			var params map[string]string
+			if err := readAssetJSON(path.Join(stackDir, "parameters.json"), &params); err != nil {
+				return err
+			}
+
+			var tags map[string]string
+			if err := readAssetJSON(path.Join(stackDir, "tags.json"), &tags); err != nil {
+				return err
+			}
+
+			log.Noticef("Applying stack '%s' from bundle '%s' ...", entry.StackName, assetsInPath)
+			if err := applier.ApplyRenderedStack(entry.StackName, string(templateBody), params, tags, ""); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+func readAssetJSON(filePath string, v interface{}) error {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -1,31 +1,37 @@
 package workflows
 
 import (
+	"encoding/base64"
 	"fmt"
 	"strconv"
 	"strings"
 
 	"github.com/stelligent/mu/common"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
 
 var ecsImagePattern = "amzn-ami-*-amazon-ecs-optimized"
 var ec2ImagePattern = "amzn-ami-hvm-*-x86_64-gp2"
 
 // NewEnvironmentUpserter create a new workflow for upserting an environment
-func NewEnvironmentUpserter(ctx *common.Context, environmentName string) Executor {
+func NewEnvironmentUpserter(ctx *common.Context, environmentName string, kubeconfigOpts common.KubeconfigOpts) Executor {
 
 	workflow := new(environmentWorkflow)
 	ecsStackParams := make(map[string]string)
 	elbStackParams := make(map[string]string)
-	workflow.codeRevision = ctx.Config.Repo.Revision
+	eksStackOutputs := make(map[string]string)
+	workflow.codeRevision = ctx.Config.Repo.Revision.String()
 	workflow.repoName = ctx.Config.Repo.Slug
+	revisionDirty := ctx.Config.Repo.Revision.Dirty
 
 	return newPipelineExecutor(
 		workflow.environmentFinder(&ctx.Config, environmentName),
 		workflow.environmentRolesetUpserter(ctx.RolesetManager, ctx.RolesetManager, ecsStackParams),
-		workflow.environmentVpcUpserter(ctx.Config.Namespace, ecsStackParams, elbStackParams, ctx.StackManager, ctx.StackManager, ctx.StackManager, ctx.StackManager),
-		workflow.environmentElbUpserter(ctx.Config.Namespace, ecsStackParams, elbStackParams, ctx.StackManager, ctx.StackManager, ctx.StackManager),
-		workflow.environmentUpserter(ctx.Config.Namespace, ecsStackParams, ctx.StackManager, ctx.StackManager, ctx.StackManager),
+		workflow.environmentVpcUpserter(ctx.Config.Namespace, ecsStackParams, elbStackParams, revisionDirty, ctx.StackManager, ctx.StackManager, ctx.StackManager, ctx.StackManager),
+		workflow.environmentElbUpserter(ctx.Config.Namespace, ecsStackParams, elbStackParams, revisionDirty, ctx.StackManager, ctx.StackManager, ctx.StackManager),
+		workflow.environmentUpserter(ctx.Config.Namespace, ecsStackParams, eksStackOutputs, revisionDirty, ctx.StackManager, ctx.StackManager, ctx.StackManager),
+		workflow.environmentKubeconfigUpserter(eksStackOutputs, kubeconfigOpts),
 	)
 }
 
@@ -51,7 +57,7 @@ func (workflow *environmentWorkflow) environmentFinder(config *common.Config, en
 	}
 }
 
-func (workflow *environmentWorkflow) environmentVpcUpserter(namespace string, ecsStackParams map[string]string, elbStackParams map[string]string, imageFinder common.ImageFinder, stackUpserter common.StackUpserter, stackWaiter common.StackWaiter, azCounter common.AZCounter) Executor {
+func (workflow *environmentWorkflow) environmentVpcUpserter(namespace string, ecsStackParams map[string]string, elbStackParams map[string]string, revisionDirty bool, imageFinder common.ImageFinder, stackUpserter common.StackUpserter, stackWaiter common.StackWaiter, azCounter common.AZCounter) Executor {
 	return func() error {
 		environment := workflow.environment
 		vpcStackParams := make(map[string]string)
@@ -118,6 +124,7 @@ func (workflow *environmentWorkflow) environmentVpcUpserter(namespace string, ec
 				Revision:    workflow.codeRevision,
 				Repo:        workflow.repoName,
 			})
+			tags["Dirty"] = strconv.FormatBool(revisionDirty)
 
 			err = stackUpserter.UpsertStack(vpcStackName, vpcTemplateName, environment, vpcStackParams, tags, workflow.cloudFormationRoleArn)
 			if err != nil {
@@ -175,7 +182,7 @@ func (workflow *environmentWorkflow) environmentRolesetUpserter(rolesetUpserter
 	}
 }
 
-func (workflow *environmentWorkflow) environmentElbUpserter(namespace string, ecsStackParams map[string]string, elbStackParams map[string]string, imageFinder common.ImageFinder, stackUpserter common.StackUpserter, stackWaiter common.StackWaiter) Executor {
+func (workflow *environmentWorkflow) environmentElbUpserter(namespace string, ecsStackParams map[string]string, elbStackParams map[string]string, revisionDirty bool, imageFinder common.ImageFinder, stackUpserter common.StackUpserter, stackWaiter common.StackWaiter) Executor {
 	return func() error {
 		environment := workflow.environment
 		envStackName := common.CreateStackName(namespace, common.StackTypeLoadBalancer, environment.Name)
@@ -214,6 +221,7 @@ func (workflow *environmentWorkflow) environmentElbUpserter(namespace string, ec
 			Revision:    workflow.codeRevision,
 			Repo:        workflow.repoName,
 		})
+		tags["Dirty"] = strconv.FormatBool(revisionDirty)
 
 		err := stackUpserter.UpsertStack(envStackName, "elb.yml", environment, stackParams, tags, workflow.cloudFormationRoleArn)
 		if err != nil {
@@ -235,7 +243,7 @@ func (workflow *environmentWorkflow) environmentElbUpserter(namespace string, ec
 	}
 }
 
-func (workflow *environmentWorkflow) environmentUpserter(namespace string, ecsStackParams map[string]string,
+func (workflow *environmentWorkflow) environmentUpserter(namespace string, ecsStackParams map[string]string, eksStackOutputs map[string]string, revisionDirty bool,
 	imageFinder common.ImageFinder, stackUpserter common.StackUpserter,
 	stackWaiter common.StackWaiter) Executor {
 	return func() error {
@@ -260,6 +268,10 @@ func (workflow *environmentWorkflow) environmentUpserter(namespace string, ecsSt
 			common.EnvProviderEc2: map[string]string{
 				"templateName": "env-ec2.yml",
 				"imagePattern": ec2ImagePattern,
+				"launchType":   ""},
+			common.EnvProviderEks: map[string]string{
+				"templateName": "env-eks.yml",
+				"imagePattern": "",
 				"launchType":   ""}}
 		templateName = envMapping[environment.Provider]["templateName"]
 		imagePattern = envMapping[environment.Provider]["imagePattern"]
@@ -278,7 +290,11 @@ func (workflow *environmentWorkflow) environmentUpserter(namespace string, ecsSt
 		if environment.Cluster.ExtraUserData != "" {
 			stackParams["ExtraUserData"] = environment.Cluster.ExtraUserData
 		}
-		if environment.Cluster.ImageID != "" {
+		if environment.Provider == common.EnvProviderEks {
+			// managed node groups resolve their own EKS-optimized AMI; nothing to look up.
+			// The cluster is named explicitly so the kubeconfig step can refer to it by name.
+			stackParams["ClusterName"] = fmt.Sprintf("%s-%s", namespace, environment.Name)
+		} else if environment.Cluster.ImageID != "" {
 			stackParams["ImageId"] = environment.Cluster.ImageID
 		} else {
 			var err error
@@ -320,6 +336,7 @@ func (workflow *environmentWorkflow) environmentUpserter(namespace string, ecsSt
 			Revision:    workflow.codeRevision,
 			Repo:        workflow.repoName,
 		})
+		tags["Dirty"] = strconv.FormatBool(revisionDirty)
 
 		err := stackUpserter.UpsertStack(envStackName, templateName, environment, stackParams, tags, workflow.cloudFormationRoleArn)
 		if err != nil {
@@ -335,6 +352,80 @@ func (workflow *environmentWorkflow) environmentUpserter(namespace string, ecsSt
 			return fmt.Errorf("Ended in failed status %s %s", stack.Status, stack.StatusReason)
 		}
 
+		if environment.Provider == common.EnvProviderEks {
+			eksStackOutputs["ClusterName"] = fmt.Sprintf("%s-%s", namespace, environment.Name)
+			eksStackOutputs["ClusterEndpoint"] = stack.Outputs["EksClusterEndpoint"]
+			eksStackOutputs["ClusterCertificateAuthority"] = stack.Outputs["EksClusterCertificateAuthority"]
+			eksStackOutputs["ClusterArn"] = stack.Outputs["EksClusterArn"]
+		}
+
 		return nil
 	}
 }
+
+// environmentKubeconfigUpserter writes/merges a kubeconfig entry for an EKS environment, pointing
+// `kubectl` at the cluster via the `aws eks get-token` exec plugin.  No-op for non-EKS providers,
+// and also a no-op when the env stack wasn't actually applied (plan mode's and asset-generation
+// mode's StackWaiter both return a stub status with no Outputs) — otherwise it would overwrite
+// the operator's current-context with a cluster entry that has no real endpoint/CA.
+func (workflow *environmentWorkflow) environmentKubeconfigUpserter(eksStackOutputs map[string]string, opts common.KubeconfigOpts) Executor {
+	return func() error {
+		environment := workflow.environment
+		if environment.Provider != common.EnvProviderEks {
+			return nil
+		}
+		if eksStackOutputs["ClusterEndpoint"] == "" || eksStackOutputs["ClusterCertificateAuthority"] == "" {
+			log.Debugf("No EKS stack outputs available (plan/assets-out mode); skipping kubeconfig generation")
+			return nil
+		}
+
+		kubeconfigPath := opts.Path
+		if kubeconfigPath == "" {
+			kubeconfigPath = clientcmd.RecommendedHomeFile
+		}
+
+		clusterName := eksStackOutputs["ClusterName"]
+		contextName := fmt.Sprintf("mu-%s", clusterName)
+
+		ca, err := base64.StdEncoding.DecodeString(eksStackOutputs["ClusterCertificateAuthority"])
+		if err != nil {
+			return fmt.Errorf("unable to decode cluster CA for '%s': %v", clusterName, err)
+		}
+
+		config, err := clientcmd.LoadFromFile(kubeconfigPath)
+		if err != nil {
+			log.Debugf("No existing kubeconfig at '%s', starting a new one: %v", kubeconfigPath, err)
+			config = clientcmdapi.NewConfig()
+		}
+
+		execArgs := []string{"eks", "get-token", "--cluster-name", clusterName}
+		if opts.RoleArn != "" {
+			execArgs = append(execArgs, "--role-arn", opts.RoleArn)
+		}
+
+		exec := &clientcmdapi.ExecConfig{
+			APIVersion: "client.authentication.k8s.io/v1beta1",
+			Command:    "aws",
+			Args:       execArgs,
+		}
+		if opts.SessionName != "" {
+			exec.Env = append(exec.Env, clientcmdapi.ExecEnvVar{Name: "AWS_ROLE_SESSION_NAME", Value: opts.SessionName})
+		}
+
+		config.Clusters[clusterName] = &clientcmdapi.Cluster{
+			Server:                   eksStackOutputs["ClusterEndpoint"],
+			CertificateAuthorityData: ca,
+		}
+		config.AuthInfos[contextName] = &clientcmdapi.AuthInfo{
+			Exec: exec,
+		}
+		config.Contexts[contextName] = &clientcmdapi.Context{
+			Cluster:  clusterName,
+			AuthInfo: contextName,
+		}
+		config.CurrentContext = contextName
+
+		log.Noticef("Writing kubeconfig context '%s' to '%s' ...", contextName, kubeconfigPath)
+		return clientcmd.WriteToFile(*config, kubeconfigPath)
+	}
+}
@@ -0,0 +1,41 @@
+package workflows
+
+import (
+	"fmt"
+
+	"github.com/stelligent/mu/common"
+)
+
+// PipelineSourceStackParams computes the CloudFormation parameters describing the
+// CodePipeline source action for a repository, picking CodeStarConnections for providers
+// that support it (GitHub, GitHub Enterprise, GitLab, Bitbucket) and CodeCommit's native
+// source action for CodeCommit.  connectionArn comes from `pipeline.source.connectionArn`
+// in mu.yml and is required for every CodeStarConnections-backed provider.
+//
+// There is no pipeline upsert workflow or pipeline.yml template in this tree to fold the
+// result into (this repo's baseline commit never included them), so this has no caller yet.
+// A NewPipelineUpserter, once added, would call this with ctx.Config.Repo.Source (itself
+// populated by the same out-of-tree config loader that needs to wire up FindGitRevision --
+// see common/git.go) and ctx.Config.Pipeline.Source.ConnectionArn, then merge the result into
+// its own stack parameters the way NewEnvironmentUpserter merges ecsStackParams/elbStackParams.
+func PipelineSourceStackParams(source common.GitSource, connectionArn string) (map[string]string, error) {
+	switch source.Provider {
+	case common.GitProviderGitHub, common.GitProviderGitHubEnterprise,
+		common.GitProviderGitLab, common.GitProviderBitbucket, common.GitProviderBitbucketServer:
+		if connectionArn == "" {
+			return nil, fmt.Errorf("pipeline.source.connectionArn is required for git provider '%s'", source.Provider)
+		}
+		return map[string]string{
+			"SourceActionProvider": "CodeStarSourceConnection",
+			"ConnectionArn":        connectionArn,
+			"FullRepositoryId":     source.Slug(),
+		}, nil
+	case common.GitProviderCodeCommit:
+		return map[string]string{
+			"SourceActionProvider": "CodeCommit",
+			"RepositoryName":       source.Repo,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported git provider '%s' for pipeline source", source.Provider)
+	}
+}
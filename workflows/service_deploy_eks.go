@@ -0,0 +1,114 @@
+package workflows
+
+import (
+	"fmt"
+
+	"github.com/stelligent/mu/common"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// NewServiceEksDeployer creates a workflow that deploys a service to an EKS environment's
+// cluster via the Kubernetes REST client, for environments whose `Provider` is
+// `common.EnvProviderEks`.  No service workflow in this tree dispatches to it; nothing here
+// calls it.
+func NewServiceEksDeployer(ctx *common.Context, environmentName string, serviceName string, image string, servicePort int, kubeconfigOpts common.KubeconfigOpts) Executor {
+	namespace := ctx.Config.Namespace
+	eksStackOutputs := map[string]string{
+		"ClusterName": fmt.Sprintf("%s-%s", namespace, environmentName),
+	}
+	return serviceEksDeployer(namespace, serviceName, image, servicePort, eksStackOutputs, kubeconfigOpts)
+}
+
+// serviceEksDeployer applies a Deployment and Service manifest for a service to an EKS
+// environment's cluster, used in place of the ECS task/service upsert when
+// `environment.Provider` is `common.EnvProviderEks`
+func serviceEksDeployer(namespace string, serviceName string, image string, servicePort int, eksStackOutputs map[string]string, kubeconfigOpts common.KubeconfigOpts) Executor {
+	return func() error {
+		kubeconfigPath := kubeconfigOpts.Path
+		if kubeconfigPath == "" {
+			kubeconfigPath = clientcmd.RecommendedHomeFile
+		}
+
+		restConfig, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+		if err != nil {
+			return fmt.Errorf("unable to load kubeconfig for cluster '%s': %v", eksStackOutputs["ClusterName"], err)
+		}
+
+		clientset, err := kubernetes.NewForConfig(restConfig)
+		if err != nil {
+			return err
+		}
+
+		log.Noticef("Deploying service '%s' to EKS cluster '%s' ...", serviceName, eksStackOutputs["ClusterName"])
+
+		// A freshly-upserted cluster only has the default/kube-system namespaces, so the
+		// target namespace must be created before the Deployment/Service can be.
+		namespaces := clientset.CoreV1().Namespaces()
+		if _, err := namespaces.Get(namespace, metav1.GetOptions{}); err != nil {
+			if !kerrors.IsNotFound(err) {
+				return err
+			}
+			nsObj := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+			if _, err := namespaces.Create(nsObj); err != nil && !kerrors.IsAlreadyExists(err) {
+				return fmt.Errorf("unable to create namespace '%s': %v", namespace, err)
+			}
+		}
+
+		labels := map[string]string{"mu.service": serviceName}
+		replicas := int32(1)
+
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: serviceName, Namespace: namespace, Labels: labels},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Name:  serviceName,
+								Image: image,
+								Ports: []corev1.ContainerPort{{ContainerPort: int32(servicePort)}},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		deployments := clientset.AppsV1().Deployments(namespace)
+		if _, err := deployments.Get(serviceName, metav1.GetOptions{}); err != nil {
+			_, err = deployments.Create(deployment)
+		} else {
+			_, err = deployments.Update(deployment)
+		}
+		if err != nil {
+			return err
+		}
+
+		service := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: serviceName, Namespace: namespace, Labels: labels},
+			Spec: corev1.ServiceSpec{
+				Selector: labels,
+				Ports:    []corev1.ServicePort{{Port: int32(servicePort), TargetPort: intstr.FromInt(servicePort)}},
+			},
+		}
+
+		services := clientset.CoreV1().Services(namespace)
+		if existing, err := services.Get(serviceName, metav1.GetOptions{}); err != nil {
+			_, err = services.Create(service)
+		} else {
+			service.Spec.ClusterIP = existing.Spec.ClusterIP // ClusterIP is immutable once assigned
+			service.ResourceVersion = existing.ResourceVersion
+			_, err = services.Update(service)
+		}
+		return err
+	}
+}